@@ -0,0 +1,105 @@
+package sweeper
+
+import "unicode/utf8"
+
+// Peek returns the next n bytes without advancing the reader. The bytes
+// stop being valid at the next read call. If Peek returns fewer than n
+// bytes, it also returns an error explaining why the read is short. The
+// error is ErrBufferFull if n is larger than the Sweeper's maximum buffer
+// size.
+func (s *Sweeper) Peek(n int) ([]byte, error) {
+	if n < 0 {
+		return nil, ErrNegativeCount
+	}
+
+	s.lastByte = -1
+	s.lastRuneSize = -1
+
+	for s.w-s.r < n && s.err == nil {
+		s.fill()
+	}
+
+	if avail := s.w - s.r; avail >= n {
+		return s.buf[s.r : s.r+n], nil
+	}
+
+	err := s.readErr()
+	if err == nil {
+		err = ErrBufferFull
+	}
+	return s.buf[s.r:s.w], err
+}
+
+// ReadByte reads and returns a single byte. If no byte is available,
+// it returns an error.
+func (s *Sweeper) ReadByte() (byte, error) {
+	s.lastRuneSize = -1
+	for s.r == s.w {
+		if s.err != nil {
+			return 0, s.readErr()
+		}
+		s.fill()
+	}
+	c := s.buf[s.r]
+	s.r++
+	s.lastByte = int(c)
+	return c, nil
+}
+
+// UnreadByte unreads the last byte. Only the most recently read byte can
+// be unread.
+//
+// UnreadByte returns an error if the most recent method called on the
+// Sweeper was not a read operation, or if the read operation did not read
+// a byte to unread.
+func (s *Sweeper) UnreadByte() error {
+	if s.lastByte < 0 || s.r == 0 && s.w > 0 {
+		return ErrInvalidUnreadByte
+	}
+	// s.r > 0 || s.w == 0
+	if s.r > 0 {
+		s.r--
+	} else {
+		// s.r == 0 && s.w == 0
+		s.w = 1
+	}
+	s.buf[s.r] = byte(s.lastByte)
+	s.lastByte = -1
+	s.lastRuneSize = -1
+	return nil
+}
+
+// ReadRune reads a single UTF-8 encoded rune and returns the rune and its
+// size in bytes. If the encoded rune is invalid, it consumes one byte and
+// returns unicode.ReplacementChar (U+FFFD) with a size of 1.
+func (s *Sweeper) ReadRune() (r rune, size int, err error) {
+	for s.r+utf8.UTFMax > s.w && !utf8.FullRune(s.buf[s.r:s.w]) && s.err == nil {
+		s.fill()
+	}
+	s.lastRuneSize = -1
+	if s.r == s.w {
+		return 0, 0, s.readErr()
+	}
+	r, size = rune(s.buf[s.r]), 1
+	if r >= utf8.RuneSelf {
+		r, size = utf8.DecodeRune(s.buf[s.r:s.w])
+	}
+	s.r += size
+	s.lastByte = int(s.buf[s.r-1])
+	s.lastRuneSize = size
+	return r, size, nil
+}
+
+// UnreadRune unreads the last rune. If the most recent method called on
+// the Sweeper was not a ReadRune, UnreadRune returns an error. As a
+// consequence, UnreadRune cannot be used to undo the last byte read via
+// UnreadByte.
+func (s *Sweeper) UnreadRune() error {
+	if s.lastRuneSize < 0 || s.r < s.lastRuneSize {
+		return ErrInvalidUnreadRune
+	}
+	s.r -= s.lastRuneSize
+	s.lastByte = -1
+	s.lastRuneSize = -1
+	return nil
+}
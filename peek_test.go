@@ -0,0 +1,94 @@
+package sweeper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPeekDoesNotAdvance(t *testing.T) {
+	s := NewSweeper(strings.NewReader("hello"))
+
+	got, err := s.Peek(3)
+	if err != nil {
+		t.Fatalf("Peek: %v", err)
+	}
+	if string(got) != "hel" {
+		t.Fatalf("Peek(3) = %q, want %q", got, "hel")
+	}
+	if s.Buffered() != 5 {
+		t.Fatalf("Buffered() = %d after Peek, want 5 (unadvanced)", s.Buffered())
+	}
+
+	b, err := s.ReadByte()
+	if err != nil || b != 'h' {
+		t.Fatalf("ReadByte() = %q, %v, want 'h', nil", b, err)
+	}
+}
+
+func TestPeekBeyondMaxBufReturnsErrBufferFull(t *testing.T) {
+	s := NewSweeperSizeMax(strings.NewReader(strings.Repeat("x", 20)), 4, 8)
+
+	_, err := s.Peek(9)
+	if err != ErrBufferFull {
+		t.Fatalf("err = %v, want ErrBufferFull", err)
+	}
+}
+
+func TestReadByteAndUnreadByte(t *testing.T) {
+	s := NewSweeper(strings.NewReader("ab"))
+
+	b, err := s.ReadByte()
+	if err != nil || b != 'a' {
+		t.Fatalf("ReadByte() = %q, %v, want 'a', nil", b, err)
+	}
+
+	if err := s.UnreadByte(); err != nil {
+		t.Fatalf("UnreadByte: %v", err)
+	}
+
+	b, err = s.ReadByte()
+	if err != nil || b != 'a' {
+		t.Fatalf("ReadByte() after UnreadByte = %q, %v, want 'a', nil", b, err)
+	}
+
+	// A second UnreadByte without an intervening read is invalid.
+	if err := s.UnreadByte(); err != nil {
+		t.Fatalf("UnreadByte: %v", err)
+	}
+	if err := s.UnreadByte(); err != ErrInvalidUnreadByte {
+		t.Fatalf("second UnreadByte err = %v, want ErrInvalidUnreadByte", err)
+	}
+}
+
+func TestReadRuneAndUnreadRune(t *testing.T) {
+	s := NewSweeper(strings.NewReader("héllo"))
+
+	r, size, err := s.ReadRune()
+	if err != nil || r != 'h' || size != 1 {
+		t.Fatalf("ReadRune() = %q, %d, %v, want 'h', 1, nil", r, size, err)
+	}
+
+	r, size, err = s.ReadRune()
+	if err != nil || r != 'é' || size != 2 {
+		t.Fatalf("ReadRune() = %q, %d, %v, want 'é', 2, nil", r, size, err)
+	}
+
+	if err := s.UnreadRune(); err != nil {
+		t.Fatalf("UnreadRune: %v", err)
+	}
+
+	r, size, err = s.ReadRune()
+	if err != nil || r != 'é' || size != 2 {
+		t.Fatalf("ReadRune() after UnreadRune = %q, %d, %v, want 'é', 2, nil", r, size, err)
+	}
+
+	// UnreadByte following a ReadRune unreads only the rune's trailing
+	// byte, same as bufio.Reader.
+	wantLastByte := "é"[1]
+	if err := s.UnreadByte(); err != nil {
+		t.Fatalf("UnreadByte after ReadRune: %v", err)
+	}
+	if b, err := s.ReadByte(); err != nil || b != wantLastByte {
+		t.Fatalf("ReadByte() after UnreadByte = %q, %v, want %q, nil", b, err, wantLastByte)
+	}
+}
@@ -0,0 +1,43 @@
+package sweeper
+
+import "bytes"
+
+// ReadBytes reads until the first occurrence of delim in the input,
+// returning a slice containing the data up to and including the
+// delimiter. Unlike ReadSliceWithString, the returned slice is a fresh
+// copy owned by the caller and is not invalidated by subsequent reads,
+// and the delimiter may appear anywhere in the stream rather than only
+// within the current buffer window.
+//
+// If ReadBytes encounters an error before finding a delimiter, it returns
+// the data read so far and the error itself (often io.EOF). ReadBytes
+// returns err != nil if and only if the returned data does not end in
+// delim.
+func (s *Sweeper) ReadBytes(delim []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	for {
+		chunk, err := s.ReadSliceWithString(delim)
+		buf.Write(chunk)
+
+		switch err {
+		case nil:
+			return buf.Bytes(), nil
+		case ErrBufferFull:
+			// The buffer filled up before the delimiter was found; the
+			// chunk read so far has already been saved above, so just
+			// keep searching; any tail that could begin the delimiter is
+			// still buffered for the next call.
+			continue
+		default:
+			return buf.Bytes(), err
+		}
+	}
+}
+
+// ReadString is like ReadBytes but returns a string instead of a
+// []byte.
+func (s *Sweeper) ReadString(delim string) (string, error) {
+	data, err := s.ReadBytes([]byte(delim))
+	return string(data), err
+}
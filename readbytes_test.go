@@ -0,0 +1,85 @@
+package sweeper
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReadBytesFindsDelimiter(t *testing.T) {
+	s := NewSweeper(strings.NewReader("one,two,three"))
+
+	got, err := s.ReadBytes([]byte(","))
+	if err != nil {
+		t.Fatalf("ReadBytes: %v", err)
+	}
+	if string(got) != "one," {
+		t.Fatalf("ReadBytes = %q, want %q", got, "one,")
+	}
+}
+
+func TestReadBytesEOFReturnsPartialData(t *testing.T) {
+	s := NewSweeper(strings.NewReader("no delimiter"))
+
+	got, err := s.ReadBytes([]byte(","))
+	if err != io.EOF {
+		t.Fatalf("err = %v, want io.EOF", err)
+	}
+	if string(got) != "no delimiter" {
+		t.Fatalf("ReadBytes = %q, want %q", got, "no delimiter")
+	}
+}
+
+func TestReadStringMatchesReadBytes(t *testing.T) {
+	s := NewSweeper(strings.NewReader("a=b;c=d"))
+
+	got, err := s.ReadString(";")
+	if err != nil {
+		t.Fatalf("ReadString: %v", err)
+	}
+	if got != "a=b;" {
+		t.Fatalf("ReadString = %q, want %q", got, "a=b;")
+	}
+}
+
+// TestReadBytesDelimiterStraddlesErrBufferFull is a regression test: the
+// delimiter's bytes can be split across the point where fill() gives up
+// growing with ErrBufferFull. ReadBytes must still find it instead of
+// running off to EOF and swallowing the rest of the stream.
+func TestReadBytesDelimiterStraddlesErrBufferFull(t *testing.T) {
+	input := strings.Repeat("x", 8) + "AB" + "tail"
+	s := NewSweeperSizeMax(strings.NewReader(input), 4, 9)
+
+	got, err := s.ReadBytes([]byte("AB"))
+	if err != nil {
+		t.Fatalf("ReadBytes: unexpected error: %v", err)
+	}
+	want := strings.Repeat("x", 8) + "AB"
+	if string(got) != want {
+		t.Fatalf("ReadBytes = %q, want %q", got, want)
+	}
+
+	rest, err := io.ReadAll(s)
+	if err != nil {
+		t.Fatalf("ReadAll rest: %v", err)
+	}
+	if string(rest) != "tail" {
+		t.Fatalf("remaining data = %q, want %q", rest, "tail")
+	}
+}
+
+// TestReadBytesAccumulatesAcrossMultipleErrBufferFullRetries checks that
+// ReadBytes keeps appending across repeated ErrBufferFull retries rather
+// than stopping at the first one.
+func TestReadBytesAccumulatesAcrossMultipleErrBufferFullRetries(t *testing.T) {
+	payload := strings.Repeat("y", 50) + "|"
+	s := NewSweeperSizeMax(strings.NewReader(payload), 4, 8)
+
+	got, err := s.ReadBytes([]byte("|"))
+	if err != nil {
+		t.Fatalf("ReadBytes: unexpected error: %v", err)
+	}
+	if string(got) != payload {
+		t.Fatalf("ReadBytes = %q (len %d), want %q (len %d)", got, len(got), payload, len(payload))
+	}
+}
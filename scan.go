@@ -0,0 +1,233 @@
+package sweeper
+
+import (
+	"bytes"
+	"io"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SplitFunc is the signature of the function used to tokenize the input.
+// It is called with data, the remainder of the buffered input, and atEOF,
+// a flag indicating whether the underlying Sweeper has no more data to give.
+// The return values are the number of bytes to advance the input and the
+// next token to return, if any, along with an error, if any.
+//
+// If the data does not yet hold a complete token, for instance if it has
+// no newline while scanning lines, SplitFunc can return (0, nil, nil) to
+// signal the caller to read more data and try again with a longer slice
+// starting at the same point in the input.
+//
+// If the returned error is non-nil, scanning stops and the error is
+// returned to the client.
+type SplitFunc func(data []byte, atEOF bool) (advance int, token []byte, err error)
+
+// SweeperScanner provides a convenient interface for tokenizing a Sweeper,
+// such as into lines or space-separated words, modeled on bufio.Scanner.
+// It operates directly on the wrapped Sweeper's buffer, so the two should
+// not be used independently once a SweeperScanner has been created.
+type SweeperScanner struct {
+	s     *Sweeper
+	split SplitFunc
+	token []byte
+	err   error
+	done  bool
+}
+
+// NewSweeperScanner returns a new SweeperScanner to read from s.
+// The split function defaults to ScanLines.
+func NewSweeperScanner(s *Sweeper) *SweeperScanner {
+	return &SweeperScanner{s: s, split: ScanLines}
+}
+
+// Split sets the split function for the SweeperScanner. It must be called
+// before Scan.
+func (sc *SweeperScanner) Split(split SplitFunc) {
+	sc.split = split
+}
+
+// Buffer sets the initial buffer to use for scanning and the maximum size
+// of buffer that may be allocated during scanning. It discards any data
+// buffered so far.
+func (sc *SweeperScanner) Buffer(buf []byte, max int) {
+	if cap(buf) == 0 {
+		buf = make([]byte, minReadBufferSize)
+	}
+	sc.s.buf = buf[:cap(buf)]
+	sc.s.r, sc.s.w = 0, 0
+	sc.s.maxBuf = max
+}
+
+// Err returns the first non-EOF error encountered by the SweeperScanner.
+func (sc *SweeperScanner) Err() error {
+	return sc.err
+}
+
+// Bytes returns the most recent token generated by a call to Scan. The
+// underlying array may point into the Sweeper's buffer and is only valid
+// until the next call to Scan.
+func (sc *SweeperScanner) Bytes() []byte {
+	return sc.token
+}
+
+// Text returns the most recent token generated by a call to Scan as a newly
+// allocated string.
+func (sc *SweeperScanner) Text() string {
+	return string(sc.token)
+}
+
+// Scan advances the SweeperScanner to the next token, which will then be
+// available through the Bytes or Text method. It returns false when the
+// scan stops, either by reaching the end of the input or an error.
+func (sc *SweeperScanner) Scan() bool {
+	if sc.done {
+		return false
+	}
+	s := sc.s
+
+	for {
+		// Only a genuine end of stream is reported to split as atEOF. A
+		// sticky error such as ErrBufferFull means no more bytes are
+		// coming right now, not that the stream has ended, so split
+		// funcs must never be told to invent a final token out of it.
+		atEOF := s.err == io.EOF
+
+		if s.w > s.r || atEOF {
+			advance, token, err := sc.split(s.buf[s.r:s.w], atEOF)
+			if err != nil {
+				sc.err = err
+				sc.done = true
+				return false
+			}
+			s.r += advance
+			if token != nil {
+				sc.token = token
+				return true
+			}
+			if atEOF {
+				sc.done = true
+				return false
+			}
+		}
+
+		if s.err != nil {
+			// No token was produced above and no more data can be read
+			// (e.g. ErrBufferFull): report the error instead of asking
+			// split to keep trying against data that will never grow.
+			sc.done = true
+			sc.err = s.err
+			return false
+		}
+
+		s.fill()
+	}
+}
+
+// dropCR drops a trailing carriage return from data.
+func dropCR(data []byte) []byte {
+	if len(data) > 0 && data[len(data)-1] == '\r' {
+		return data[0 : len(data)-1]
+	}
+	return data
+}
+
+// ScanLines is a SplitFunc for a SweeperScanner that returns each line of
+// text, stripping any trailing end-of-line marker. The returned line may
+// be empty. The end-of-line marker is one optional carriage return
+// followed by one mandatory newline.
+func ScanLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, dropCR(data[0:i]), nil
+	}
+	if atEOF {
+		return len(data), dropCR(data), nil
+	}
+	return 0, nil, nil
+}
+
+// ScanBytes is a SplitFunc for a SweeperScanner that returns each byte of
+// input as its own token.
+func ScanBytes(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	return 1, data[0:1], nil
+}
+
+// errorRune is the token returned by ScanRunes in place of invalid UTF-8.
+var errorRune = []byte(string(utf8.RuneError))
+
+// ScanRunes is a SplitFunc for a SweeperScanner that returns each UTF-8
+// encoded rune of input as its own token. Invalid UTF-8 encodings are
+// replaced with a single token holding utf8.RuneError.
+func ScanRunes(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	if data[0] < utf8.RuneSelf {
+		return 1, data[0:1], nil
+	}
+
+	_, width := utf8.DecodeRune(data)
+	if width > 1 {
+		if !atEOF && !utf8.FullRune(data) {
+			return 0, nil, nil
+		}
+		return width, data[0:width], nil
+	}
+
+	if !atEOF && !utf8.FullRune(data) {
+		return 0, nil, nil
+	}
+
+	return 1, errorRune, nil
+}
+
+// ScanWords is a SplitFunc for a SweeperScanner that returns each
+// space-separated word of text, with surrounding whitespace deleted.
+func ScanWords(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	start := 0
+	for width := 0; start < len(data); start += width {
+		var r rune
+		r, width = utf8.DecodeRune(data[start:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+	}
+	if atEOF && len(data) == start {
+		return len(data), nil, nil
+	}
+	for width, i := 0, start; i < len(data); i += width {
+		var r rune
+		r, width = utf8.DecodeRune(data[i:])
+		if unicode.IsSpace(r) {
+			return i + width, data[start:i], nil
+		}
+	}
+	if atEOF && len(data) > start {
+		return len(data), data[start:], nil
+	}
+	return start, nil, nil
+}
+
+// ScanDelimiter returns a SplitFunc for a SweeperScanner that returns each
+// token terminated by delim, with the delimiter included. This is the
+// Scanner equivalent of Sweeper.ReadSliceWithString.
+func ScanDelimiter(delim []byte) SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		if i := bytes.Index(data, delim); i >= 0 {
+			return i + len(delim), data[0 : i+len(delim)], nil
+		}
+		if atEOF {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
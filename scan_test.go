@@ -0,0 +1,129 @@
+package sweeper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSweeperScannerScanLines(t *testing.T) {
+	sc := NewSweeperScanner(NewSweeper(strings.NewReader("one\r\ntwo\nthree")))
+
+	var got []string
+	for sc.Scan() {
+		got = append(got, sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatalf("Err() = %v, want nil", err)
+	}
+
+	want := []string{"one", "two", "three"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v lines, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("line %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSweeperScannerScanWords(t *testing.T) {
+	sc := NewSweeperScanner(NewSweeper(strings.NewReader("  the quick  brown fox ")))
+	sc.Split(ScanWords)
+
+	var got []string
+	for sc.Scan() {
+		got = append(got, sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatalf("Err() = %v, want nil", err)
+	}
+
+	want := []string{"the", "quick", "brown", "fox"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("word %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSweeperScannerScanBytes(t *testing.T) {
+	sc := NewSweeperScanner(NewSweeper(strings.NewReader("ab")))
+	sc.Split(ScanBytes)
+
+	var got []byte
+	for sc.Scan() {
+		got = append(got, sc.Bytes()...)
+	}
+	if string(got) != "ab" {
+		t.Fatalf("got %q, want %q", got, "ab")
+	}
+}
+
+func TestSweeperScannerScanDelimiter(t *testing.T) {
+	sc := NewSweeperScanner(NewSweeper(strings.NewReader("a::b::c")))
+	sc.Split(ScanDelimiter([]byte("::")))
+
+	var got []string
+	for sc.Scan() {
+		got = append(got, sc.Text())
+	}
+
+	want := []string{"a::", "b::", "c"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("token %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSweeperScannerBufferGrowsWithinMax(t *testing.T) {
+	long := strings.Repeat("a", 100) + "\n"
+	sc := NewSweeperScanner(NewSweeperSizeMax(strings.NewReader(long), 8, 8))
+	sc.Buffer(make([]byte, 8), 1024)
+
+	if !sc.Scan() {
+		t.Fatalf("Scan() = false, err = %v, want a single long line", sc.Err())
+	}
+	if len(sc.Text()) != 100 {
+		t.Fatalf("len(Text()) = %d, want 100", len(sc.Text()))
+	}
+}
+
+// TestSweeperScannerLineExceedsMaxBufReturnsErrBufferFull is a regression
+// test: a line that never fits, even after the buffer has grown to its
+// ceiling, must surface ErrBufferFull rather than have Scan() return a
+// truncated line as if it were a genuine final token.
+func TestSweeperScannerLineExceedsMaxBufReturnsErrBufferFull(t *testing.T) {
+	long := strings.Repeat("a", 100) // no trailing newline, never terminates
+	sc := NewSweeperScanner(NewSweeperSizeMax(strings.NewReader(long), 4, 16))
+
+	if sc.Scan() {
+		t.Fatalf("Scan() = true with token %q, want false (line exceeds maxBuf)", sc.Text())
+	}
+	if sc.Err() != ErrBufferFull {
+		t.Fatalf("Err() = %v, want ErrBufferFull", sc.Err())
+	}
+}
+
+// TestSweeperScannerScanDelimiterExceedsMaxBufReturnsErrBufferFull checks
+// the same overflow behavior for a custom delimiter split that the
+// stream never supplies.
+func TestSweeperScannerScanDelimiterExceedsMaxBufReturnsErrBufferFull(t *testing.T) {
+	long := strings.Repeat("a", 100) // no delimiter anywhere in the stream
+	sc := NewSweeperScanner(NewSweeperSizeMax(strings.NewReader(long), 4, 16))
+	sc.Split(ScanDelimiter([]byte("::")))
+
+	if sc.Scan() {
+		t.Fatalf("Scan() = true with token %q, want false (delimiter never fits)", sc.Text())
+	}
+	if sc.Err() != ErrBufferFull {
+		t.Fatalf("Err() = %v, want ErrBufferFull", sc.Err())
+	}
+}
@@ -10,24 +10,54 @@ const (
 	defaultBufSize           = 256
 	minReadBufferSize        = 1
 	maxConsecutiveEmptyReads = 100
+
+	// DefaultMaxBufSize is the buffer size ceiling used by NewSweeper and
+	// NewSweeperSize. Use NewSweeperSizeMax to raise or lower it.
+	DefaultMaxBufSize = 1 << 20 // 1 MiB
 )
 
 var (
 	errNegativeRead = errors.New("sweeper: reader returned negative count from Read")
+
+	// ErrBufferFull is returned when the buffer needs to grow past its
+	// configured maximum size to make progress.
+	ErrBufferFull = errors.New("sweeper: buffer full")
+
+	// ErrInvalidUnreadByte is returned by UnreadByte when no byte is
+	// available to unread, for instance when the last operation was not a
+	// read.
+	ErrInvalidUnreadByte = errors.New("sweeper: invalid use of UnreadByte")
+
+	// ErrInvalidUnreadRune is returned by UnreadRune when no rune is
+	// available to unread, or ReadRune was not the last operation.
+	ErrInvalidUnreadRune = errors.New("sweeper: invalid use of UnreadRune")
+
+	// ErrNegativeCount is returned by Peek when n is negative.
+	ErrNegativeCount = errors.New("sweeper: negative count")
 )
 
 // Sweeper implements buffering for an io.Reader object.
 type Sweeper struct {
-	buf  []byte
-	rd   io.Reader // reader provided by the client
-	r, w int       // buf read and write positions
-	err  error
+	buf          []byte
+	rd           io.Reader // reader provided by the client
+	r, w         int       // buf read and write positions
+	err          error
+	maxBuf       int // buffer will not grow past this size
+	lastByte     int // last byte read for UnreadByte; -1 means invalid
+	lastRuneSize int // size of last rune read for UnreadRune; -1 means invalid
 }
 
 // NewSweeperSize returns a new Sweeper whose buffer has at least the specified
 // size. If the argument io.Reader is already a Reader with large enough
 // size, it returns the underlying Sweeper.
 func NewSweeperSize(rd io.Reader, size int) *Sweeper {
+	return NewSweeperSizeMax(rd, size, DefaultMaxBufSize)
+}
+
+// NewSweeperSizeMax is like NewSweeperSize but also bounds how large the
+// internal buffer is allowed to grow. Once growing past max would be
+// required to make progress, fill reports ErrBufferFull instead.
+func NewSweeperSizeMax(rd io.Reader, size, max int) *Sweeper {
 	// Is it already a Reader?
 	s, ok := rd.(*Sweeper)
 	if ok && len(s.buf) >= size {
@@ -36,8 +66,12 @@ func NewSweeperSize(rd io.Reader, size int) *Sweeper {
 	if size < minReadBufferSize {
 		size = minReadBufferSize
 	}
+	if max < size {
+		max = size
+	}
 	r := new(Sweeper)
 	r.reset(make([]byte, size), rd)
+	r.maxBuf = max
 	return r
 }
 
@@ -59,9 +93,13 @@ func (s *Sweeper) Reset(r io.Reader) {
 }
 
 func (s *Sweeper) reset(buf []byte, r io.Reader) {
+	maxBuf := s.maxBuf
 	*s = Sweeper{
-		buf: buf,
-		rd:  r,
+		buf:          buf,
+		rd:           r,
+		maxBuf:       maxBuf,
+		lastByte:     -1,
+		lastRuneSize: -1,
 	}
 }
 
@@ -71,42 +109,40 @@ func (s *Sweeper) readErr() error {
 	return err
 }
 
-// isZero is a helper function to find if a byte slice is all zeroes.
-func (s *Sweeper) isBufZero() bool {
-	for _, v := range s.buf {
-		if v != 0 {
-			return false
-		}
-	}
-	return true
-}
-
+// fill reads more data into the buffer, compacting and growing it as
+// necessary. Any unread bytes (s.buf[s.r:s.w]) are first slid down to the
+// front of the buffer. The buffer is only grown, by doubling, once it is
+// completely full; growth stops at maxBuf and reports ErrBufferFull.
 func (s *Sweeper) fill() {
-	s.buf = append(s.buf, make([]byte, 1)...)
+	s.lastByte = -1
+	s.lastRuneSize = -1
 
-	// if the read position is greater than zero then the delimiter was found.
+	// Slide existing data to the front of the buffer.
 	if s.r > 0 {
-		// Since the delimiter was found we may reset the buffer back to its
-		// original size to clean up.
-		temp := s.buf[s.r:]
-		s.buf = make([]byte, defaultBufSize)
-		copy(s.buf, temp)
-
-		// Just set the read and write positions to 0 so then it can scan
-		// from the beginning of the slice when it begins again.
-		s.w = len(temp) - 1
+		copy(s.buf, s.buf[s.r:s.w])
+		s.w -= s.r
 		s.r = 0
 	}
 
 	if s.w >= len(s.buf) {
-		panic("bufio: tried to fill full buffer")
+		if len(s.buf) >= s.maxBuf {
+			s.err = ErrBufferFull
+			return
+		}
+		newSize := len(s.buf) * 2
+		if newSize == 0 {
+			newSize = minReadBufferSize
+		}
+		if newSize > s.maxBuf {
+			newSize = s.maxBuf
+		}
+		newBuf := make([]byte, newSize)
+		copy(newBuf, s.buf[:s.w])
+		s.buf = newBuf
 	}
 
 	// Read new data: try a limited number of times.
 	for i := maxConsecutiveEmptyReads; i > 0; i-- {
-		// Reads the length of the data that's not part of the already
-		// existing data that I appended earlier. This means that it will
-		// search a total of one byte in this function call.
 		n, err := s.rd.Read(s.buf[s.w:])
 		if n < 0 {
 			panic(errNegativeRead)
@@ -129,48 +165,54 @@ func (s *Sweeper) fill() {
 // Any bytes that is after the delimiter is saved for the next read.
 // If ReadSliceWithString encounters an error before finding a delimiter,
 // it returns all the data in the buffer and the error itself.
-// Although if the error is for EOF it keeps running until there isn't any
-// data left and is just zeroed out.
-// ReadSlice returns err != nil if and only if line does not end in delim.
+// ReadSliceWithString returns err != nil if and only if line does not end
+// in delim. The exception to returning all buffered data is ErrBufferFull:
+// then up to len(delim)-1 trailing bytes that could begin the delimiter
+// stay buffered for the next call.
 func (s *Sweeper) ReadSliceWithString(delim []byte) (line []byte, err error) {
-	s.fill() // Fill the buffer with data
-
 	for {
 		// Search buffer.
-		if i := bytes.Index(s.buf[s.r:], []byte(delim)); i >= 0 {
-			line = s.buf[:i+len(delim)]
-			s.r = i + len(delim)
+		if i := bytes.Index(s.buf[s.r:s.w], delim); i >= 0 {
+			line = s.buf[s.r : s.r+i+len(delim)]
+			s.r += i + len(delim)
+			break
+		}
 
+		// The buffer gave up growing before the delimiter was found. The
+		// delimiter may straddle this boundary, so keep the tail that
+		// could still be its prefix buffered and unconsumed instead of
+		// handing it over: a retry after more data arrives may complete
+		// the match.
+		if s.err == ErrBufferFull {
+			keep := len(delim) - 1
+			if keep < 0 {
+				keep = 0
+			}
+			if avail := s.w - s.r; keep > avail {
+				keep = avail
+			}
+			line = s.buf[s.r : s.w-keep]
+			s.r = s.w - keep
+			err = s.readErr()
 			break
 		}
 
 		// Pending error?
-		if s.err != nil && s.err != io.EOF {
+		if s.err != nil {
 			line = s.buf[s.r:s.w]
 			s.r = s.w
 			err = s.readErr()
 			break
 		}
 
-		// Note: This function does not check the buffered size in comparison
-		// to the length of the buffer, because s.r is always zero and never
-		// incremented since we are rescanning all of the buffer all of the time.
-
-		if s.err != io.EOF {
-			s.fill()
-		} else {
-			if s.isBufZero() {
-				line = s.buf
-				s.r = s.w
-				err = s.err
-				break
-			}
-
-			s.buf = s.buf[s.r:]
+		s.fill()
+	}
 
-			s.r = 0
-		}
+	s.lastByte = -1
+	if i := len(line) - 1; i >= 0 {
+		s.lastByte = int(line[i])
 	}
+	s.lastRuneSize = -1
 
 	return
 }
@@ -0,0 +1,93 @@
+package sweeper
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestNewSweeperDefaultSize(t *testing.T) {
+	s := NewSweeper(strings.NewReader("hello"))
+	if got := s.Size(); got != defaultBufSize {
+		t.Fatalf("Size() = %d, want %d", got, defaultBufSize)
+	}
+}
+
+func TestNewSweeperSizeMaxClampsMaxToSize(t *testing.T) {
+	s := NewSweeperSizeMax(strings.NewReader(""), 16, 4)
+	if s.maxBuf != 16 {
+		t.Fatalf("maxBuf = %d, want 16 (clamped up to the requested size)", s.maxBuf)
+	}
+}
+
+func TestFillGrowsBuffer(t *testing.T) {
+	const data = "aaaa" + "bbbb" + "|"
+	s := NewSweeperSizeMax(strings.NewReader(data), 4, 64)
+
+	line, err := s.ReadSliceWithString([]byte("|"))
+	if err != nil {
+		t.Fatalf("ReadSliceWithString: unexpected error: %v", err)
+	}
+	if string(line) != data {
+		t.Fatalf("line = %q, want %q", line, data)
+	}
+	if s.Size() <= 4 {
+		t.Fatalf("expected buffer to have grown past its initial size of 4, got %d", s.Size())
+	}
+}
+
+func TestReadSliceWithStringErrBufferFull(t *testing.T) {
+	s := NewSweeperSizeMax(strings.NewReader(strings.Repeat("x", 20)), 4, 8)
+
+	line, err := s.ReadSliceWithString([]byte("\n"))
+	if err != ErrBufferFull {
+		t.Fatalf("err = %v, want ErrBufferFull", err)
+	}
+	if len(line) == 0 {
+		t.Fatalf("expected the buffered data so far, got none")
+	}
+}
+
+func TestReadSliceWithStringEOF(t *testing.T) {
+	s := NewSweeper(strings.NewReader("no newline here"))
+
+	line, err := s.ReadSliceWithString([]byte("\n"))
+	if err != io.EOF {
+		t.Fatalf("err = %v, want io.EOF", err)
+	}
+	if string(line) != "no newline here" {
+		t.Fatalf("line = %q", line)
+	}
+}
+
+func TestReadReturnsBufferedData(t *testing.T) {
+	s := NewSweeperSize(strings.NewReader("abcdef"), 4)
+
+	got, err := io.ReadAll(s)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(got) != "abcdef" {
+		t.Fatalf("got %q, want %q", got, "abcdef")
+	}
+}
+
+func TestResetDiscardsBufferedDataAndSwitchesReader(t *testing.T) {
+	s := NewSweeper(strings.NewReader("abc"))
+	if _, err := s.Read(make([]byte, 1)); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	s.Reset(strings.NewReader("xyz"))
+	if buffered := s.Buffered(); buffered != 0 {
+		t.Fatalf("Buffered() = %d after Reset, want 0", buffered)
+	}
+
+	got, err := io.ReadAll(s)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(got) != "xyz" {
+		t.Fatalf("got %q after Reset, want %q", got, "xyz")
+	}
+}
@@ -0,0 +1,288 @@
+package sweeper
+
+import (
+	"errors"
+	"io"
+	"unicode/utf8"
+)
+
+var errNegativeWrite = errors.New("sweeper: writer returned negative count from Write")
+
+// SweeperWriter implements buffering for an io.Writer object. If an error
+// occurs writing to a SweeperWriter, no more data will be accepted and all
+// subsequent writes, and Flush, will return the error. After all data has
+// been written, the client should call the Flush method to guarantee all
+// data has been forwarded to the underlying io.Writer.
+type SweeperWriter struct {
+	err error
+	buf []byte
+	n   int
+	wr  io.Writer
+}
+
+// NewWriterSize returns a new SweeperWriter whose buffer has at least the
+// specified size. If the argument io.Writer is already a SweeperWriter
+// with large enough size, it returns the underlying SweeperWriter.
+func NewWriterSize(w io.Writer, size int) *SweeperWriter {
+	// Is it already a Writer?
+	b, ok := w.(*SweeperWriter)
+	if ok && len(b.buf) >= size {
+		return b
+	}
+	if size <= 0 {
+		size = defaultBufSize
+	}
+	return &SweeperWriter{
+		buf: make([]byte, size),
+		wr:  w,
+	}
+}
+
+// NewWriter returns a new SweeperWriter whose buffer has the default size.
+func NewWriter(w io.Writer) *SweeperWriter {
+	return NewWriterSize(w, defaultBufSize)
+}
+
+// Size returns the size of the underlying buffer in bytes.
+func (b *SweeperWriter) Size() int { return len(b.buf) }
+
+// Reset discards any unflushed buffered data, clears any error, and resets
+// the SweeperWriter to write its output to w.
+func (b *SweeperWriter) Reset(w io.Writer) {
+	b.err = nil
+	b.n = 0
+	b.wr = w
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (b *SweeperWriter) Flush() error {
+	if b.err != nil {
+		return b.err
+	}
+	if b.n == 0 {
+		return nil
+	}
+
+	n, err := b.wr.Write(b.buf[0:b.n])
+	if n < b.n && err == nil {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		if n > 0 && n < b.n {
+			copy(b.buf[0:b.n-n], b.buf[n:b.n])
+		}
+		b.n -= n
+		b.err = err
+		return err
+	}
+	b.n = 0
+	return nil
+}
+
+// Available returns how many bytes are unused in the buffer.
+func (b *SweeperWriter) Available() int { return len(b.buf) - b.n }
+
+// Buffered returns the number of bytes that have been written into the
+// current buffer.
+func (b *SweeperWriter) Buffered() int { return b.n }
+
+// Write writes the contents of p into the buffer. It returns the number
+// of bytes written. If nn < len(p), it also returns an error explaining
+// why the write is short.
+func (b *SweeperWriter) Write(p []byte) (nn int, err error) {
+	for len(p) > b.Available() && b.err == nil {
+		var n int
+		if b.Buffered() == 0 {
+			// Large write, empty buffer. Write directly from p to avoid copy.
+			n, b.err = b.wr.Write(p)
+		} else {
+			n = copy(b.buf[b.n:], p)
+			b.n += n
+			b.Flush()
+		}
+		nn += n
+		p = p[n:]
+	}
+	if b.err != nil {
+		return nn, b.err
+	}
+	n := copy(b.buf[b.n:], p)
+	b.n += n
+	nn += n
+	return nn, nil
+}
+
+// WriteByte writes a single byte.
+func (b *SweeperWriter) WriteByte(c byte) error {
+	if b.err != nil {
+		return b.err
+	}
+	if b.Available() <= 0 && b.Flush() != nil {
+		return b.err
+	}
+	b.buf[b.n] = c
+	b.n++
+	return nil
+}
+
+// WriteRune writes a single Unicode code point, returning the number of
+// bytes written and any error.
+func (b *SweeperWriter) WriteRune(r rune) (size int, err error) {
+	if uint32(r) < utf8.RuneSelf {
+		err = b.WriteByte(byte(r))
+		if err != nil {
+			return 0, err
+		}
+		return 1, nil
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	n := b.Available()
+	if n < utf8.UTFMax {
+		if b.Flush(); b.err != nil {
+			return 0, b.err
+		}
+		n = b.Available()
+		if n < utf8.UTFMax {
+			return b.WriteString(string(r))
+		}
+	}
+	size = utf8.EncodeRune(b.buf[b.n:], r)
+	b.n += size
+	return size, nil
+}
+
+// WriteString writes a string. It returns the number of bytes written.
+// If the count is less than len(s), it also returns an error explaining
+// why the write is short.
+func (b *SweeperWriter) WriteString(s string) (int, error) {
+	var sw io.StringWriter
+	tryStringWriter := true
+
+	nn := 0
+	for len(s) > b.Available() && b.err == nil {
+		var n int
+		if b.Buffered() == 0 && sw == nil && tryStringWriter {
+			sw, tryStringWriter = b.wr.(io.StringWriter)
+		}
+		if b.Buffered() == 0 && tryStringWriter {
+			n, b.err = sw.WriteString(s)
+		} else {
+			n = copy(b.buf[b.n:], s)
+			b.n += n
+			b.Flush()
+		}
+		nn += n
+		s = s[n:]
+	}
+	if b.err != nil {
+		return nn, b.err
+	}
+	n := copy(b.buf[b.n:], s)
+	b.n += n
+	nn += n
+	return nn, nil
+}
+
+// ReadFrom implements io.ReaderFrom, reading from r until EOF or error
+// and writing the data to the underlying io.Writer, flushing as the
+// buffer fills up. This lets io.Copy between two Sweepers avoid an
+// intermediate allocation.
+func (b *SweeperWriter) ReadFrom(r io.Reader) (n int64, err error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	readerFrom, readerFromOK := b.wr.(io.ReaderFrom)
+	var m int
+	for {
+		if b.Available() == 0 {
+			if err1 := b.Flush(); err1 != nil {
+				return n, err1
+			}
+		}
+		if readerFromOK && b.Buffered() == 0 {
+			nn, err := readerFrom.ReadFrom(r)
+			b.err = err
+			n += nn
+			return n, err
+		}
+		nr := 0
+		for nr < maxConsecutiveEmptyReads {
+			m, err = r.Read(b.buf[b.n:])
+			if m != 0 || err != nil {
+				break
+			}
+			nr++
+		}
+		if nr == maxConsecutiveEmptyReads {
+			return n, io.ErrNoProgress
+		}
+		b.n += m
+		n += int64(m)
+		if err != nil {
+			break
+		}
+	}
+	if err == io.EOF {
+		if err1 := b.Flush(); err1 != nil {
+			err = err1
+		} else {
+			err = nil
+		}
+	}
+	return n, err
+}
+
+// WriteTo implements io.WriterTo, draining the currently buffered data
+// and then copying directly from the underlying reader to w, avoiding an
+// intermediate allocation where possible.
+func (s *Sweeper) WriteTo(w io.Writer) (n int64, err error) {
+	s.lastByte = -1
+	s.lastRuneSize = -1
+
+	n, err = s.writeBuf(w)
+	if err != nil {
+		return
+	}
+
+	if r, ok := s.rd.(io.WriterTo); ok {
+		m, err := r.WriteTo(w)
+		n += m
+		return n, err
+	}
+
+	if wt, ok := w.(io.ReaderFrom); ok {
+		m, err := wt.ReadFrom(s.rd)
+		n += m
+		return n, err
+	}
+
+	if s.w-s.r < len(s.buf) {
+		s.fill() // buffer is empty
+	}
+
+	for s.r < s.w {
+		m, err := s.writeBuf(w)
+		n += m
+		if err != nil {
+			return n, err
+		}
+		s.fill() // buffer is empty
+	}
+
+	if s.err == io.EOF {
+		s.err = nil
+	}
+
+	return n, s.readErr()
+}
+
+func (s *Sweeper) writeBuf(w io.Writer) (int64, error) {
+	n, err := w.Write(s.buf[s.r:s.w])
+	if n < 0 {
+		panic(errNegativeWrite)
+	}
+	s.r += n
+	return int64(n), err
+}
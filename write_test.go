@@ -0,0 +1,116 @@
+package sweeper
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSweeperWriterFlushesOnBufferFull(t *testing.T) {
+	var dst bytes.Buffer
+	w := NewWriterSize(&dst, 4)
+
+	n, err := w.Write([]byte("abcdefgh"))
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if n != 8 {
+		t.Fatalf("n = %d, want 8", n)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if dst.String() != "abcdefgh" {
+		t.Fatalf("dst = %q, want %q", dst.String(), "abcdefgh")
+	}
+}
+
+func TestSweeperWriterWriteByteAndWriteRune(t *testing.T) {
+	var dst bytes.Buffer
+	w := NewWriter(&dst)
+
+	if err := w.WriteByte('h'); err != nil {
+		t.Fatalf("WriteByte: %v", err)
+	}
+	if _, err := w.WriteRune('é'); err != nil {
+		t.Fatalf("WriteRune: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if dst.String() != "hé" {
+		t.Fatalf("dst = %q, want %q", dst.String(), "hé")
+	}
+}
+
+func TestSweeperWriterWriteString(t *testing.T) {
+	var dst bytes.Buffer
+	w := NewWriterSize(&dst, 4)
+
+	if _, err := w.WriteString("hello world"); err != nil {
+		t.Fatalf("WriteString: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if dst.String() != "hello world" {
+		t.Fatalf("dst = %q, want %q", dst.String(), "hello world")
+	}
+}
+
+func TestSweeperWriterReadFrom(t *testing.T) {
+	var dst bytes.Buffer
+	w := NewWriterSize(&dst, 4)
+
+	n, err := w.ReadFrom(strings.NewReader("the quick brown fox"))
+	if err != nil {
+		t.Fatalf("ReadFrom: %v", err)
+	}
+	if n != int64(len("the quick brown fox")) {
+		t.Fatalf("n = %d, want %d", n, len("the quick brown fox"))
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if dst.String() != "the quick brown fox" {
+		t.Fatalf("dst = %q, want %q", dst.String(), "the quick brown fox")
+	}
+}
+
+func TestSweeperWriteTo(t *testing.T) {
+	s := NewSweeperSize(strings.NewReader("the quick brown fox"), 4)
+
+	var dst bytes.Buffer
+	n, err := s.WriteTo(&dst)
+	if err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	if n != int64(len("the quick brown fox")) {
+		t.Fatalf("n = %d, want %d", n, len("the quick brown fox"))
+	}
+	if dst.String() != "the quick brown fox" {
+		t.Fatalf("dst = %q, want %q", dst.String(), "the quick brown fox")
+	}
+}
+
+func TestSweeperWriteToAfterPartialBuffer(t *testing.T) {
+	s := NewSweeperSize(strings.NewReader("abcdef"), 4)
+
+	// Buffer a few bytes first so WriteTo has to drain the existing
+	// window before falling back to copying from the reader.
+	if _, err := s.Peek(2); err != nil {
+		t.Fatalf("Peek: %v", err)
+	}
+
+	var dst bytes.Buffer
+	n, err := s.WriteTo(&dst)
+	if err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	if n != 6 {
+		t.Fatalf("n = %d, want 6", n)
+	}
+	if dst.String() != "abcdef" {
+		t.Fatalf("dst = %q, want %q", dst.String(), "abcdef")
+	}
+}